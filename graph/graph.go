@@ -0,0 +1,43 @@
+// Package graph holds the Wikipedia link graph built up by the parse
+// package and rendered by the visual package.
+package graph
+
+// Node is a single page in the link graph, identified by its full title
+// path. Outgoing links are kept on the node itself rather than in a
+// separate adjacency map.
+type Node struct {
+	Name  string
+	Links []*Node
+}
+
+// Graph is a Wikipedia link graph, indexed by title path in a Store so
+// that callers can look up, walk, or prune it by namespace without
+// scanning every node.
+type Graph struct {
+	Store *Store[*Node]
+}
+
+// NewGraph creates an empty Graph.
+func NewGraph() *Graph {
+	return &Graph{Store: NewStore[*Node]()}
+}
+
+// GetOrCreate returns the existing node for name, creating and indexing a
+// new one if it isn't present yet. The lookup and creation happen
+// atomically with respect to other Graph callers, so concurrent pipeline
+// stages (parse.GetLinks and parse.GetCategories both write to the same
+// Graph) can't end up creating two different nodes for the same name.
+func (g *Graph) GetOrCreate(name string) *Node {
+	return g.Store.GetOrPut(name, func() *Node {
+		return &Node{Name: name}
+	})
+}
+
+// AddEdge records a link from `from` to `to`, creating either node if it
+// doesn't already exist in the store.
+func (g *Graph) AddEdge(from, to string) {
+	fromNode := g.GetOrCreate(from)
+	toNode := g.GetOrCreate(to)
+
+	fromNode.Links = append(fromNode.Links, toNode)
+}
@@ -0,0 +1,112 @@
+package graph
+
+import (
+	"sync"
+
+	radix "github.com/armon/go-radix"
+)
+
+// Store is a radix-tree-backed index keyed by Wikipedia title path
+// (e.g. "Category:Foo/Bar", "Talk:Baz"). Unlike a flat map it lets callers
+// enumerate everything under a namespace prefix, find the closest ancestor
+// of a title, or drop a whole namespace in a single traversal instead of a
+// full scan.
+//
+// The underlying radix tree isn't safe for concurrent use on its own, and
+// Store is written to from multiple pipeline stages at once (GetLinks and
+// GetCategories both call Graph.AddEdge concurrently), so every method
+// takes a mutex.
+type Store[T any] struct {
+	mu   sync.Mutex
+	tree *radix.Tree
+}
+
+// NewStore creates an empty Store.
+func NewStore[T any]() *Store[T] {
+	return &Store[T]{tree: radix.New()}
+}
+
+// Put inserts or overwrites the value stored under key.
+func (s *Store[T]) Put(key string, value T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tree.Insert(key, value)
+}
+
+// Get returns the value stored under key, if any.
+func (s *Store[T]) Get(key string) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.tree.Get(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+
+	return v.(T), true
+}
+
+// GetOrPut returns the value stored under key, calling construct and
+// inserting its result under key if nothing was stored yet. The lookup
+// and insert happen under a single lock, so concurrent callers racing to
+// create the same key are guaranteed to see (and share) the same value.
+func (s *Store[T]) GetOrPut(key string, construct func() T) T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if v, ok := s.tree.Get(key); ok {
+		return v.(T)
+	}
+
+	v := construct()
+	s.tree.Insert(key, v)
+
+	return v
+}
+
+// WalkPrefix calls fn for every entry whose key starts with prefix, in
+// lexical order, stopping early if fn returns false. The Store is locked
+// for the duration of the walk, so fn must not call back into the same
+// Store.
+func (s *Store[T]) WalkPrefix(prefix string, fn func(T) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tree.WalkPrefix(prefix, func(key string, v interface{}) bool {
+		return !fn(v.(T))
+	})
+}
+
+// LongestPrefix returns the value whose key is the longest prefix of key,
+// e.g. the closest ancestor page for a title that hasn't been parsed yet.
+func (s *Store[T]) LongestPrefix(key string) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, v, ok := s.tree.LongestPrefix(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+
+	return v.(T), true
+}
+
+// Delete removes the entry stored under key, if any.
+func (s *Store[T]) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tree.Delete(key)
+}
+
+// DeletePrefix removes every entry whose key starts with prefix, e.g. to
+// drop an entire namespace in one call.
+func (s *Store[T]) DeletePrefix(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tree.DeletePrefix(prefix)
+}
@@ -0,0 +1,190 @@
+package parse
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+const defaultMemoryFraction = 0.25
+
+// CacheOptions configures a Cache's soft memory cap and disk spill
+// location.
+type CacheOptions struct {
+	// SoftCapBytes overrides the automatic cap when non-zero.
+	SoftCapBytes uint64
+	// SpillDir is where evicted pages are written; defaults to
+	// os.TempDir() when empty.
+	SpillDir string
+}
+
+type cacheEntry struct {
+	page Page
+	size uint64
+}
+
+// Cache is an LRU of parsed pages keyed by title, soft-capped by an
+// estimate of the memory it holds rather than by item count. Pages
+// evicted under memory pressure are spilled to disk instead of dropped,
+// so a later Get for the same title still succeeds, just slower. The cap
+// defaults to KAPOK_MEMORYLIMIT GiB if set, otherwise a quarter of total
+// system memory as reported by gopsutil.
+type Cache struct {
+	mu      sync.Mutex
+	entries *lru.Cache[string, cacheEntry]
+	spill   *spillStore
+	softCap uint64
+	size    uint64
+}
+
+// NewCache creates a Cache soft-capped per opts.
+func NewCache(opts CacheOptions) (*Cache, error) {
+	softCap := opts.SoftCapBytes
+
+	if softCap == 0 {
+		softCap = softCapFromEnv()
+	}
+
+	if softCap == 0 {
+		softCap = defaultSoftCap()
+	}
+
+	spillDir := opts.SpillDir
+	if spillDir == "" {
+		spillDir = os.TempDir()
+	}
+
+	spill, err := newSpillStore(spillDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// The underlying LRU is sized generously; Put evicts on softCap, not
+	// on this count, so it should never actually fill up in practice.
+	entries, err := lru.New[string, cacheEntry](1 << 20)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cache{
+		entries: entries,
+		spill:   spill,
+		softCap: softCap,
+	}, nil
+}
+
+func softCapFromEnv() uint64 {
+	raw := os.Getenv("KAPOK_MEMORYLIMIT")
+	if raw == "" {
+		return 0
+	}
+
+	gib, err := strconv.ParseFloat(raw, 64)
+	if err != nil || gib <= 0 {
+		return 0
+	}
+
+	return uint64(gib * (1 << 30))
+}
+
+func defaultSoftCap() uint64 {
+	vm, err := mem.VirtualMemory()
+	if err != nil {
+		return 1 << 30 // Couldn't even ask the OS; fall back to 1 GiB.
+	}
+
+	return uint64(float64(vm.Total) * defaultMemoryFraction)
+}
+
+// Put inserts page under title, evicting and spilling least-recently-used
+// entries to disk until the cache is back under its soft cap.
+func (c *Cache) Put(title string, page Page) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if old, ok := c.entries.Peek(title); ok {
+		c.size -= old.size
+	}
+
+	size := approxSize(page)
+
+	c.entries.Add(title, cacheEntry{page: page, size: size})
+	c.size += size
+
+	for c.size > c.softCap {
+		oldTitle, oldEntry, ok := c.entries.RemoveOldest()
+		if !ok {
+			break
+		}
+
+		c.size -= oldEntry.size
+
+		if err := c.spill.put(oldTitle, oldEntry.page); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get returns the page stored under title, falling back to the disk
+// spill if it's been evicted from memory.
+func (c *Cache) Get(title string) (Page, bool) {
+	c.mu.Lock()
+	entry, ok := c.entries.Get(title)
+	c.mu.Unlock()
+
+	if ok {
+		return entry.page, true
+	}
+
+	return c.spill.get(title)
+}
+
+// CachePages threads every page through c before forwarding it: each page
+// is stored under its title and immediately re-read with c.Get, so the
+// rest of the pipeline (and anything downstream holding pages by title)
+// only ever retains what fits under c's soft cap instead of every page
+// parsed so far.
+func CachePages(c *Cache, pages <-chan Page, cachedPages chan<- Page) {
+	for {
+		select {
+		case page, ok := <-pages:
+			if !ok {
+				close(cachedPages)
+				return
+			}
+
+			if err := c.Put(page.Title(), page); err != nil {
+				log.Println(err.Error() + " caching page, passing through uncached")
+				cachedPages <- page
+				continue
+			}
+
+			cached, ok := c.Get(page.Title())
+			if !ok {
+				cached = page
+			}
+
+			cachedPages <- cached
+		}
+	}
+}
+
+func approxSize(page Page) uint64 {
+	size := uint64(len(page.Title()) + len(page.Text()))
+
+	for _, link := range page.Links() {
+		size += uint64(len(link))
+	}
+
+	for _, cat := range page.Categories() {
+		size += uint64(len(cat))
+	}
+
+	return size
+}
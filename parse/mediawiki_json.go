@@ -0,0 +1,70 @@
+package parse
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// MediaWikiJSONPage is a Page decoded from the MediaWiki Action API's JSON
+// export format, e.g. the `query.pages` list produced by
+// `action=query&prop=revisions|categories&rvslots=main`.
+type MediaWikiJSONPage struct {
+	PageTitle string `json:"title"`
+	Ns        int    `json:"ns"`
+	Revisions []struct {
+		Slots struct {
+			Main struct {
+				Content string `json:"content"`
+			} `json:"main"`
+		} `json:"slots"`
+	} `json:"revisions"`
+	CategoryList []struct {
+		Title string `json:"title"`
+	} `json:"categories"`
+
+	links      []string
+	categories []string
+}
+
+var _ Page = (*MediaWikiJSONPage)(nil)
+
+func (p *MediaWikiJSONPage) Title() string { return p.PageTitle }
+
+func (p *MediaWikiJSONPage) Text() string {
+	if len(p.Revisions) == 0 {
+		return ""
+	}
+
+	return p.Revisions[0].Slots.Main.Content
+}
+
+func (p *MediaWikiJSONPage) Links() []string { return p.links }
+
+func (p *MediaWikiJSONPage) Categories() []string {
+	if p.categories != nil {
+		return p.categories
+	}
+
+	cats := make([]string, len(p.CategoryList))
+
+	for i, cat := range p.CategoryList {
+		cats[i] = strings.TrimPrefix(cat.Title, "Category:")
+	}
+
+	return cats
+}
+
+func (p *MediaWikiJSONPage) Namespace() int { return p.Ns }
+func (p *MediaWikiJSONPage) Source() string { return "mediawiki-api-json" }
+
+func (p *MediaWikiJSONPage) setLinks(links []string)           { p.links = links }
+func (p *MediaWikiJSONPage) setCategories(categories []string) { p.categories = categories }
+
+// UnmarshalMediaWikiJSONPage parses a single page object from a MediaWiki
+// API JSON dump.
+func UnmarshalMediaWikiJSONPage(raw []byte) (*MediaWikiJSONPage, error) {
+	page := &MediaWikiJSONPage{}
+	err := json.Unmarshal(raw, page)
+
+	return page, err
+}
@@ -0,0 +1,24 @@
+package parse
+
+// Page is a single page from any supported source: a Wikipedia XML dump,
+// a MediaWiki API JSON dump, or a plain-text link list. Everything else
+// in this package works against Page so it doesn't need to know which
+// one it's looking at.
+type Page interface {
+	Title() string
+	Text() string
+	Links() []string
+	Categories() []string
+	Namespace() int
+	// Source identifies where this page came from, e.g. a file path or a
+	// dump format tag.
+	Source() string
+}
+
+// pageBuilder is implemented by Page types whose links and categories are
+// filled in by the pipeline after the page is parsed, rather than being
+// known up front.
+type pageBuilder interface {
+	setLinks(links []string)
+	setCategories(categories []string)
+}
@@ -3,41 +3,65 @@ package parse
 
 import (
 	"bufio"
-	"encoding/xml"
 	"io"
 	"log"
 	"regexp"
-	"strings"
+	"sync"
+
+	"github.com/aaasen/kapok/graph"
+	"github.com/aaasen/kapok/parse/wikitext"
 )
 
 var pageStartRegex = regexp.MustCompile(".*<page>.*")
 var pageEndRegex = regexp.MustCompile(".*</page>.*")
-var redirectRegex = regexp.MustCompile("#REDIRECT[ \t].*?\\[\\[.*?\\]\\]")
-var linkRegex = regexp.MustCompile("\\[\\[([^|]+?)\\]\\]")
-var categoryRegex = regexp.MustCompile("\\[\\[Category:(.+?)\\]\\]")
-
-// Parse parses given reader as XML and dumps Page objects with links
-// into its output channel.
-func Parse(reader io.Reader, pages chan<- *Page) {
-	chunks := make(chan []byte)
-	rawPages := make(chan []byte)
-	nonRedirectPages := make(chan []byte)
-	somePages := make(chan *Page)
+
+// Parse parses given reader as XML, dumps Page objects with links into
+// its output channel, and records every link it finds as an edge in g.
+// opts bounds the queue depth and decode concurrency of the pipeline so
+// a multi-GB dump doesn't grow the heap without limit; the zero value
+// falls back to DefaultPipelineOptions. If cache is non-nil, every page
+// is threaded through it before being sent to pages, so a caller holding
+// on to pages by title is bounded by the cache's soft cap rather than by
+// the size of the dump.
+func Parse(g *graph.Graph, reader io.Reader, pages chan<- Page, opts PipelineOptions, cache *Cache) {
+	opts = opts.withDefaults()
+
+	chunks := make(chan []byte, opts.QueueSize)
+	rawPages := make(chan []byte, opts.QueueSize)
+	somePages := make(chan Page, opts.QueueSize)
+	nonRedirectPages := make(chan Page, opts.QueueSize)
 
 	go GetChunks(reader, chunks)
 	go GetRawPages(chunks, rawPages)
-	go FilterRedirects(rawPages, nonRedirectPages)
-	go GetPages(nonRedirectPages, somePages)
-	go GetLinks(somePages, pages)
+	go GetPages(rawPages, somePages, opts.Workers)
+	go FilterRedirects(somePages, nonRedirectPages)
+
+	if cache == nil {
+		go GetLinks(g, nonRedirectPages, pages)
+		return
+	}
+
+	linkedPages := make(chan Page, opts.QueueSize)
+	go GetLinks(g, nonRedirectPages, linkedPages)
+	go CachePages(cache, linkedPages, pages)
 }
 
-// CategorizedParse is just like Parse, except that it also categorizes pages.
-func CategorizedParse(reader io.Reader, out chan<- *Page) {
-	pages := make(chan *Page)
+// CategorizedParse is just like Parse, except that it also categorizes
+// pages. cache, if non-nil, sits after categorization rather than being
+// passed down to Parse, so each page is only cached once, fully built.
+func CategorizedParse(g *graph.Graph, reader io.Reader, out chan<- Page, opts PipelineOptions, cache *Cache) {
+	opts = opts.withDefaults()
+	pages := make(chan Page, opts.QueueSize)
 
-	go GetCategories(pages, out)
+	if cache == nil {
+		go GetCategories(g, pages, out)
+	} else {
+		categorizedPages := make(chan Page, opts.QueueSize)
+		go GetCategories(g, pages, categorizedPages)
+		go CachePages(cache, categorizedPages, out)
+	}
 
-	Parse(reader, pages)
+	Parse(g, reader, pages, opts, nil)
 }
 
 // GetChunks reads an XML file line by line and dumps each line to its output channel.
@@ -48,7 +72,12 @@ func GetChunks(reader io.Reader, chunks chan<- []byte) {
 
 	for !eof {
 		if scanner.Scan() {
-			chunks <- scanner.Bytes()
+			// scanner.Bytes() aliases the scanner's internal buffer, which
+			// is overwritten on the next Scan(); copy it out before handing
+			// it to a buffered channel where it can sit queued behind
+			// other chunks.
+			buf := append([]byte(nil), scanner.Bytes()...)
+			chunks <- buf
 		} else {
 			if err := scanner.Err(); err != nil {
 				log.Println(err.Error() + " skipping line")
@@ -94,49 +123,64 @@ func GetRawPages(chunks <-chan []byte, pages chan<- []byte) {
 	}
 }
 
-// FilterRedirects discards all pages that redirect to another page.
-func FilterRedirects(rawPages <-chan []byte, nonRedirectPages chan<- []byte) {
-	for {
-		select {
-		case rawPage, ok := <-rawPages:
-			if !ok {
-				close(nonRedirectPages)
-				return
-			}
+// GetPages parses complete XML pages into Pages, using `workers`
+// goroutines to decode concurrently; it closes pages once rawPages is
+// drained and every worker has finished.
+func GetPages(rawPages <-chan []byte, pages chan<- Page, workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
 
-			if redirectRegex.Find(rawPage) == nil {
-				nonRedirectPages <- rawPage
+		go func() {
+			defer wg.Done()
+
+			for rawPage := range rawPages {
+				page, err := unmarshalWikipediaXMLPage(rawPage)
+
+				if err != nil {
+
+				} else {
+					pages <- page
+				}
 			}
-		}
+		}()
 	}
+
+	wg.Wait()
+	close(pages)
 }
 
-// GetPages parses a complete XML page into a page object.
-func GetPages(rawPages <-chan []byte, pages chan<- *Page) {
+// FilterRedirects discards any page whose wikitext contains a
+// #REDIRECT [[Target]] directive anywhere in it, using the wikitext tokenizer rather than a
+// standalone regex so it agrees with GetLinks/GetCategories on what
+// counts as a redirect.
+func FilterRedirects(pages <-chan Page, nonRedirectPages chan<- Page) {
 	for {
 		select {
-		case rawPage, ok := <-rawPages:
+		case page, ok := <-pages:
 			if !ok {
-				close(pages)
+				close(nonRedirectPages)
 				return
 			}
 
-			pageStruct := &Page{}
-
-			err := xml.Unmarshal(rawPage, pageStruct)
-
-			if err != nil {
-
-			} else {
-				pages <- pageStruct
+			if !hasToken(page.Text(), wikitext.RedirectToken) {
+				nonRedirectPages <- page
 			}
 		}
 	}
 }
 
-// GetLinks extracts all Wikipedia links found in pages.
-// Only links in the form [[target]] are extracted.
-func GetLinks(pages <-chan *Page, linkedPages chan<- *Page) {
+// GetLinks extracts all links found in pages and records them as edges
+// from the page into g. Pages that already know their own links (such as
+// a PlainTextPage) are passed through unchanged; pages whose links still
+// need to be pulled out of raw wikitext have them extracted here via the
+// wikitext tokenizer.
+func GetLinks(g *graph.Graph, pages <-chan Page, linkedPages chan<- Page) {
 	for {
 		select {
 		case page, ok := <-pages:
@@ -145,10 +189,20 @@ func GetLinks(pages <-chan *Page, linkedPages chan<- *Page) {
 				return
 			}
 
-			links := linkRegex.FindAllStringSubmatch(page.Revision.Text, -1)
+			if builder, ok := page.(pageBuilder); ok && page.Text() != "" {
+				var links []string
+
+				for _, tok := range wikitext.Tokenize(page.Text()) {
+					if tok.Type == wikitext.LinkToken {
+						links = append(links, tok.Target)
+					}
+				}
 
-			for _, link := range links {
-				page.Links = append(page.Links, link[1])
+				builder.setLinks(links)
+			}
+
+			for _, link := range page.Links() {
+				g.AddEdge(page.Title(), link)
 			}
 
 			linkedPages <- page
@@ -156,10 +210,12 @@ func GetLinks(pages <-chan *Page, linkedPages chan<- *Page) {
 	}
 }
 
-// GetCategories extracts categories out of each Wikipedia page
-// and adds them to the given categories object.
-// Only links in the form [[Category:target]] are extracted.
-func GetCategories(pages <-chan *Page, categorizedPages chan<- *Page) {
+// GetCategories extracts categories out of each page and records a
+// "Category:" edge for each one in g. Pages that already know their own
+// categories are passed through unchanged; pages whose categories still
+// need to be pulled out of raw wikitext have them extracted here via the
+// wikitext tokenizer.
+func GetCategories(g *graph.Graph, pages <-chan Page, categorizedPages chan<- Page) {
 	for {
 		select {
 		case page, ok := <-pages:
@@ -168,16 +224,33 @@ func GetCategories(pages <-chan *Page, categorizedPages chan<- *Page) {
 				return
 			}
 
-			rawCats := categoryRegex.FindAllStringSubmatch(page.Revision.Text, -1)
-			cats := make([]string, len(rawCats))
+			if builder, ok := page.(pageBuilder); ok && page.Text() != "" {
+				var cats []string
 
-			for i, rawCat := range rawCats {
-				cats[i] = strings.Trim(rawCat[1], " \t|")
+				for _, tok := range wikitext.Tokenize(page.Text()) {
+					if tok.Type == wikitext.CategoryToken {
+						cats = append(cats, tok.Target)
+					}
+				}
+
+				builder.setCategories(cats)
 			}
 
-			page.Categories = cats
+			for _, cat := range page.Categories() {
+				g.AddEdge(page.Title(), "Category:"+cat)
+			}
 
 			categorizedPages <- page
 		}
 	}
 }
+
+func hasToken(text string, tokenType wikitext.TokenType) bool {
+	for _, tok := range wikitext.Tokenize(text) {
+		if tok.Type == tokenType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,30 @@
+package parse
+
+// PipelineOptions bounds how much of a dump can be in flight through
+// Parse/CategorizedParse at once, so memory grows with QueueSize rather
+// than with the size of the dump being streamed.
+type PipelineOptions struct {
+	// QueueSize is the buffer depth of every channel in the pipeline.
+	// Producers block once a stage's queue fills up instead of growing
+	// an internal buffer unboundedly.
+	QueueSize int
+	// Workers is how many goroutines run the XML-decoding stage
+	// concurrently.
+	Workers int
+}
+
+// DefaultPipelineOptions is used wherever a zero-value PipelineOptions is
+// passed to Parse or CategorizedParse.
+var DefaultPipelineOptions = PipelineOptions{QueueSize: 64, Workers: 4}
+
+func (o PipelineOptions) withDefaults() PipelineOptions {
+	if o.QueueSize <= 0 {
+		o.QueueSize = DefaultPipelineOptions.QueueSize
+	}
+
+	if o.Workers <= 0 {
+		o.Workers = DefaultPipelineOptions.Workers
+	}
+
+	return o
+}
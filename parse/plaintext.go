@@ -0,0 +1,42 @@
+package parse
+
+import "strings"
+
+// PlainTextPage adapts a plain "title\nlink\nlink\n..." record — e.g. a
+// hand-curated corpus or an export with no markup — into a Page so it
+// can flow through the same pipeline as a Wikipedia dump.
+type PlainTextPage struct {
+	PageTitle  string
+	PageLinks  []string
+	SourcePath string
+}
+
+var _ Page = (*PlainTextPage)(nil)
+
+// NewPlainTextPage parses a single record in "title\nlink\nlink..." form.
+func NewPlainTextPage(source, record string) *PlainTextPage {
+	page := &PlainTextPage{SourcePath: source}
+
+	lines := strings.Split(strings.TrimSpace(record), "\n")
+
+	if len(lines) == 0 {
+		return page
+	}
+
+	page.PageTitle = strings.TrimSpace(lines[0])
+
+	for _, line := range lines[1:] {
+		if link := strings.TrimSpace(line); link != "" {
+			page.PageLinks = append(page.PageLinks, link)
+		}
+	}
+
+	return page
+}
+
+func (p *PlainTextPage) Title() string        { return p.PageTitle }
+func (p *PlainTextPage) Text() string         { return "" }
+func (p *PlainTextPage) Links() []string      { return p.PageLinks }
+func (p *PlainTextPage) Categories() []string { return nil }
+func (p *PlainTextPage) Namespace() int       { return 0 }
+func (p *PlainTextPage) Source() string       { return p.SourcePath }
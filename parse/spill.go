@@ -0,0 +1,83 @@
+package parse
+
+import (
+	"crypto/sha1"
+	"encoding/gob"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+)
+
+// spillStore persists pages evicted from a Cache as individual gob files
+// on disk, so a Cache can hold far more pages than fit in memory at once.
+type spillStore struct {
+	dir string
+}
+
+func newSpillStore(dir string) (*spillStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &spillStore{dir: dir}, nil
+}
+
+func (s *spillStore) path(title string) string {
+	sum := sha1.Sum([]byte(title))
+
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".gob")
+}
+
+func (s *spillStore) put(title string, page Page) error {
+	f, err := os.Create(s.path(title))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return gob.NewEncoder(f).Encode(&spilledPage{
+		PageTitle:      page.Title(),
+		PageText:       page.Text(),
+		PageLinks:      page.Links(),
+		PageCategories: page.Categories(),
+		PageNamespace:  page.Namespace(),
+		PageSource:     page.Source(),
+	})
+}
+
+func (s *spillStore) get(title string) (Page, bool) {
+	f, err := os.Open(s.path(title))
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	spilled := &spilledPage{}
+
+	if err := gob.NewDecoder(f).Decode(spilled); err != nil {
+		return nil, false
+	}
+
+	return spilled, true
+}
+
+// spilledPage is the gob-encoded form of a Page written out by
+// spillStore; it's a plain value type so it round-trips regardless of
+// which concrete Page implementation produced it.
+type spilledPage struct {
+	PageTitle      string
+	PageText       string
+	PageLinks      []string
+	PageCategories []string
+	PageNamespace  int
+	PageSource     string
+}
+
+var _ Page = (*spilledPage)(nil)
+
+func (p *spilledPage) Title() string        { return p.PageTitle }
+func (p *spilledPage) Text() string         { return p.PageText }
+func (p *spilledPage) Links() []string      { return p.PageLinks }
+func (p *spilledPage) Categories() []string { return p.PageCategories }
+func (p *spilledPage) Namespace() int       { return p.PageNamespace }
+func (p *spilledPage) Source() string       { return p.PageSource }
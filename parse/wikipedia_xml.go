@@ -0,0 +1,35 @@
+package parse
+
+import "encoding/xml"
+
+// WikipediaXMLPage is a Page decoded from Wikipedia's XML database dump.
+type WikipediaXMLPage struct {
+	RawTitle string `xml:"title"`
+	Ns       int    `xml:"ns"`
+	Revision struct {
+		RawText string `xml:"text"`
+	} `xml:"revision"`
+
+	links      []string
+	categories []string
+}
+
+var _ Page = (*WikipediaXMLPage)(nil)
+
+func (p *WikipediaXMLPage) Title() string       { return p.RawTitle }
+func (p *WikipediaXMLPage) Text() string        { return p.Revision.RawText }
+func (p *WikipediaXMLPage) Links() []string     { return p.links }
+func (p *WikipediaXMLPage) Categories() []string { return p.categories }
+func (p *WikipediaXMLPage) Namespace() int      { return p.Ns }
+func (p *WikipediaXMLPage) Source() string      { return "wikipedia-xml" }
+
+func (p *WikipediaXMLPage) setLinks(links []string)           { p.links = links }
+func (p *WikipediaXMLPage) setCategories(categories []string) { p.categories = categories }
+
+// unmarshalWikipediaXMLPage parses a single <page>...</page> element.
+func unmarshalWikipediaXMLPage(rawPage []byte) (*WikipediaXMLPage, error) {
+	page := &WikipediaXMLPage{}
+	err := xml.Unmarshal(rawPage, page)
+
+	return page, err
+}
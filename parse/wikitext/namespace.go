@@ -0,0 +1,41 @@
+package wikitext
+
+import "strings"
+
+// interwikiPrefixes are link prefixes that point at a different wiki
+// entirely (another language edition or sister project), e.g.
+// [[en:Foo]] or [[wikt:Bar]]. Not exhaustive, but covers the common
+// sister-project and language-code prefixes found in enwiki dumps.
+var interwikiPrefixes = map[string]bool{
+	"en": true, "de": true, "fr": true, "es": true, "it": true,
+	"ja": true, "zh": true, "ru": true, "pt": true, "nl": true,
+	"m": true, "meta": true, "species": true,
+	"b": true, "q": true, "s": true, "n": true, "v": true, "d": true,
+	"wikt": true, "commons": true, "wikibooks": true, "wikiquote": true,
+	"wikisource": true, "wikiversity": true, "wikidata": true, "wikinews": true,
+}
+
+// fileNamespacePrefixes are namespaces for embedded media rather than
+// ordinary page links.
+var fileNamespacePrefixes = map[string]bool{
+	"file": true, "image": true, "media": true,
+}
+
+// splitNamespace splits target on its first ":" into a namespace prefix
+// and the remainder, e.g. "Category:Foo" -> ("Category", "Foo").
+func splitNamespace(target string) (prefix, rest string, ok bool) {
+	idx := strings.IndexByte(target, ':')
+	if idx == -1 {
+		return "", target, false
+	}
+
+	return target[:idx], target[idx+1:], true
+}
+
+// isNonArticleNamespace reports whether prefix names an interwiki or
+// file/media namespace rather than an ordinary same-wiki page.
+func isNonArticleNamespace(prefix string) bool {
+	lower := strings.ToLower(strings.TrimSpace(prefix))
+
+	return interwikiPrefixes[lower] || fileNamespacePrefixes[lower]
+}
@@ -0,0 +1,26 @@
+package wikitext
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizeTarget applies MediaWiki's standard title normalization: any
+// "#section" fragment is stripped, underscores become spaces, surrounding
+// whitespace is trimmed, and the first letter is uppercased.
+func normalizeTarget(target string) string {
+	if hash := strings.IndexByte(target, '#'); hash != -1 {
+		target = target[:hash]
+	}
+
+	target = strings.TrimSpace(strings.ReplaceAll(target, "_", " "))
+
+	if target == "" {
+		return target
+	}
+
+	runes := []rune(target)
+	runes[0] = unicode.ToUpper(runes[0])
+
+	return string(runes)
+}
@@ -0,0 +1,216 @@
+package wikitext
+
+import "strings"
+
+var nowikiTags = []string{"nowiki", "pre", "source"}
+
+// Tokenize scans wikitext source and returns every link, category,
+// template, redirect, and nowiki-region token it finds, in source order.
+// Text inside <!-- --> comments is skipped and yields no token; text
+// inside <nowiki>/<pre>/<source> regions is skipped, surfaced only as a
+// single NowikiToken for the region.
+func Tokenize(text string) []Token {
+	var tokens []Token
+
+	i := 0
+	n := len(text)
+
+	for i < n {
+		rest := text[i:]
+
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			if end := strings.Index(rest, "-->"); end != -1 {
+				i += end + len("-->")
+			} else {
+				i = n
+			}
+
+		case nowikiTagAt(rest) != "":
+			tag := nowikiTagAt(rest)
+			raw, next := consumeNowikiRegion(text, i, tag)
+			tokens = append(tokens, Token{Type: NowikiToken, Target: tag, Raw: raw})
+			i = next
+
+		case hasRedirectPrefix(rest):
+			if tok, next, ok := consumeRedirect(text, i); ok {
+				tokens = append(tokens, tok)
+				i = next
+			} else {
+				i++
+			}
+
+		case strings.HasPrefix(rest, "[["):
+			tok, next := consumeLink(text, i)
+			tokens = append(tokens, tok)
+			i = next
+
+		case strings.HasPrefix(rest, "{{"):
+			tok, next := consumeTemplate(text, i)
+			tokens = append(tokens, tok)
+			i = next
+
+		default:
+			i++
+		}
+	}
+
+	return tokens
+}
+
+// findMatchingClose returns the index of the close marker that matches
+// the open marker already consumed before `from`, honoring nested
+// occurrences of the same open/close pair, or -1 if unterminated.
+func findMatchingClose(text string, from int, open, closeMarker string) int {
+	depth := 1
+	i := from
+
+	for i < len(text) {
+		switch {
+		case strings.HasPrefix(text[i:], open):
+			depth++
+			i += len(open)
+		case strings.HasPrefix(text[i:], closeMarker):
+			depth--
+			if depth == 0 {
+				return i
+			}
+			i += len(closeMarker)
+		default:
+			i++
+		}
+	}
+
+	return -1
+}
+
+func consumeLink(text string, start int) (Token, int) {
+	end := findMatchingClose(text, start+2, "[[", "]]")
+	if end == -1 {
+		return Token{Type: LinkToken, Raw: text[start:]}, len(text)
+	}
+
+	raw := text[start : end+2]
+	inner := text[start+2 : end]
+
+	parts := strings.SplitN(inner, "|", 2)
+	target := parts[0]
+	display := ""
+
+	if len(parts) == 2 {
+		display = strings.TrimSpace(parts[1])
+	}
+
+	tokenType := LinkToken
+
+	if prefix, rest, ok := splitNamespace(strings.TrimSpace(target)); ok {
+		switch {
+		case strings.EqualFold(prefix, "Category"):
+			tokenType = CategoryToken
+			target = rest
+		case isNonArticleNamespace(prefix):
+			tokenType = NonArticleLinkToken
+			target = rest
+		}
+	}
+
+	return Token{
+		Type:    tokenType,
+		Target:  normalizeTarget(target),
+		Display: display,
+		Raw:     raw,
+	}, end + 2
+}
+
+func consumeTemplate(text string, start int) (Token, int) {
+	end := findMatchingClose(text, start+2, "{{", "}}")
+	if end == -1 {
+		return Token{Type: TemplateToken, Raw: text[start:]}, len(text)
+	}
+
+	raw := text[start : end+2]
+	inner := text[start+2 : end]
+	name := strings.TrimSpace(strings.SplitN(inner, "|", 2)[0])
+
+	return Token{Type: TemplateToken, Target: name, Raw: raw}, end + 2
+}
+
+// redirectMagicWord is the directive MediaWiki recognizes at the start
+// of a redirect page; matching is case-insensitive, as MediaWiki treats
+// "#redirect", "#Redirect", and "#REDIRECT" identically.
+const redirectMagicWord = "#REDIRECT"
+
+// hasRedirectPrefix reports whether s begins with the redirect magic
+// word, ignoring case.
+func hasRedirectPrefix(s string) bool {
+	return len(s) >= len(redirectMagicWord) && strings.EqualFold(s[:len(redirectMagicWord)], redirectMagicWord)
+}
+
+// consumeRedirect handles "#REDIRECT [[Target]]"; it only matches if a
+// link immediately follows the directive, so a stray "#REDIRECT" in
+// running prose isn't mistaken for one.
+func consumeRedirect(text string, start int) (Token, int, bool) {
+	rest := text[start+len(redirectMagicWord):]
+
+	openIdx := strings.Index(rest, "[[")
+	if openIdx == -1 || strings.TrimSpace(rest[:openIdx]) != "" {
+		return Token{}, 0, false
+	}
+
+	absOpen := start + len(redirectMagicWord) + openIdx
+	end := findMatchingClose(text, absOpen+2, "[[", "]]")
+
+	if end == -1 {
+		return Token{}, 0, false
+	}
+
+	target := normalizeTarget(strings.SplitN(text[absOpen+2:end], "|", 2)[0])
+
+	return Token{
+		Type:   RedirectToken,
+		Target: target,
+		Raw:    text[start : end+2],
+	}, end + 2, true
+}
+
+// nowikiTagAt returns the tag name if s opens a <nowiki>, <pre>, or
+// <source> region, or "" otherwise.
+func nowikiTagAt(s string) string {
+	if !strings.HasPrefix(s, "<") {
+		return ""
+	}
+
+	lower := strings.ToLower(s)
+
+	for _, tag := range nowikiTags {
+		if strings.HasPrefix(lower[1:], tag) {
+			return tag
+		}
+	}
+
+	return ""
+}
+
+func consumeNowikiRegion(text string, start int, tag string) (raw string, next int) {
+	openEnd := strings.IndexByte(text[start:], '>')
+	if openEnd == -1 {
+		return text[start:], len(text)
+	}
+
+	// A self-closing tag such as <nowiki/> opens no region.
+	if openEnd > 0 && text[start+openEnd-1] == '/' {
+		return text[start : start+openEnd+1], start + openEnd + 1
+	}
+
+	bodyStart := start + openEnd + 1
+	closeTag := "</" + tag + ">"
+
+	closeIdx := strings.Index(strings.ToLower(text[bodyStart:]), closeTag)
+	if closeIdx == -1 {
+		return text[start:], len(text)
+	}
+
+	end := bodyStart + closeIdx + len(closeTag)
+
+	return text[start:end], end
+}
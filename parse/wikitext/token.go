@@ -0,0 +1,41 @@
+// Package wikitext is a small scanner for Wikipedia's wikitext markup.
+// It replaces regex-based link extraction, which misses piped links,
+// interwiki prefixes, file/image links, links inside nowiki/comment
+// regions, and section anchors.
+package wikitext
+
+// TokenType identifies what kind of wikitext construct a Token captures.
+type TokenType int
+
+const (
+	// LinkToken is an ordinary [[Target]] or [[Target|Display]] link to
+	// another page on the same wiki.
+	LinkToken TokenType = iota
+	// CategoryToken is a [[Category:Target]] link.
+	CategoryToken
+	// NonArticleLinkToken is a [[Target]] link whose namespace doesn't
+	// point at another page on this wiki: an interwiki link like
+	// [[en:Foo]] or [[wikt:Bar]], or an embedded file/image link like
+	// [[File:X.jpg|thumb|...]]. Target still holds the normalized title,
+	// for callers that want it, but it isn't a page-to-page link.
+	NonArticleLinkToken
+	// TemplateToken is a {{template invocation}}.
+	TemplateToken
+	// RedirectToken is a #REDIRECT [[Target]] directive.
+	RedirectToken
+	// NowikiToken marks a <nowiki>, <pre>, or <source> region whose
+	// contents were skipped rather than scanned for markup.
+	NowikiToken
+)
+
+// Token is a single piece of wikitext markup found by Tokenize.
+type Token struct {
+	Type TokenType
+	// Target is the normalized page title for Link/Category/Redirect
+	// tokens, or the tag name for a NowikiToken.
+	Target string
+	// Display is the piped display text of a link, if any.
+	Display string
+	// Raw is the unmodified source text the token was scanned from.
+	Raw string
+}
@@ -0,0 +1,153 @@
+package layout
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/aaasen/kapok/graph"
+)
+
+// ForceDirectedOptions tunes ForceDirected's simulation.
+type ForceDirectedOptions struct {
+	// Iterations is how many cooling steps to run.
+	Iterations int
+	// Theta is the Barnes-Hut approximation threshold: a region of the
+	// quadtree is treated as a single mass once its size/distance ratio
+	// drops below this. ~0.5 is the standard default.
+	Theta float64
+}
+
+// DefaultForceDirectedOptions is used wherever a zero-value
+// ForceDirectedOptions is passed to ForceDirected.Layout.
+var DefaultForceDirectedOptions = ForceDirectedOptions{Iterations: 200, Theta: 0.5}
+
+// ForceDirected lays a graph out with Fruchterman-Reingold: every pair of
+// nodes repels with f_r(d) = k^2/d, every edge attracts its endpoints
+// with f_a(d) = d^2/k, and each node's summed displacement per step is
+// capped by a temperature that cools linearly to zero. The repulsive sum
+// is approximated with a Barnes-Hut quadtree so it costs O(n log n) per
+// step rather than O(n^2).
+type ForceDirected struct {
+	ForceDirectedOptions
+}
+
+var _ Layouter = ForceDirected{}
+
+func (f ForceDirected) Layout(g *graph.Graph, width, height int) map[*graph.Node]Vector {
+	opts := f.ForceDirectedOptions
+
+	if opts.Iterations <= 0 {
+		opts.Iterations = DefaultForceDirectedOptions.Iterations
+	}
+
+	if opts.Theta <= 0 {
+		opts.Theta = DefaultForceDirectedOptions.Theta
+	}
+
+	nodes := collectNodes(g)
+	n := len(nodes)
+
+	if n == 0 {
+		return map[*graph.Node]Vector{}
+	}
+
+	k := math.Sqrt(float64(width*height) / float64(n))
+
+	positions := make([]Vector, n)
+	index := make(map[*graph.Node]int, n)
+
+	for i, node := range nodes {
+		positions[i] = Vector{X: rand.Float64() * float64(width), Y: rand.Float64() * float64(height)}
+		index[node] = i
+	}
+
+	initialTemperature := float64(width) / 10
+	temperature := initialTemperature
+
+	for step := 0; step < opts.Iterations; step++ {
+		displacement := make([]Vector, n)
+
+		tree := newQuadtree(0, 0, float64(width), float64(height))
+		for i, p := range positions {
+			tree.insert(p, i)
+		}
+
+		for i, p := range positions {
+			tree.forces(p, i, opts.Theta, func(dx, dy, d float64, weight int) {
+				force := (k * k / d) * float64(weight)
+				displacement[i].X += (dx / d) * force
+				displacement[i].Y += (dy / d) * force
+			})
+		}
+
+		for i, node := range nodes {
+			for _, neighbor := range node.Links {
+				j, ok := index[neighbor]
+				if !ok {
+					continue
+				}
+
+				dx := positions[i].X - positions[j].X
+				dy := positions[i].Y - positions[j].Y
+				d := math.Hypot(dx, dy)
+
+				if d == 0 {
+					d = 0.01
+				}
+
+				force := (d * d) / k
+
+				displacement[i].X -= (dx / d) * force
+				displacement[i].Y -= (dy / d) * force
+				displacement[j].X += (dx / d) * force
+				displacement[j].Y += (dy / d) * force
+			}
+		}
+
+		for i := range positions {
+			d := math.Hypot(displacement[i].X, displacement[i].Y)
+
+			if d > 0 {
+				capped := math.Min(d, temperature)
+				positions[i].X += (displacement[i].X / d) * capped
+				positions[i].Y += (displacement[i].Y / d) * capped
+			}
+
+			positions[i].X = clamp(positions[i].X, 0, float64(width))
+			positions[i].Y = clamp(positions[i].Y, 0, float64(height))
+		}
+
+		temperature = initialTemperature * (1 - float64(step+1)/float64(opts.Iterations))
+	}
+
+	result := make(map[*graph.Node]Vector, n)
+
+	for i, node := range nodes {
+		result[node] = positions[i]
+	}
+
+	return result
+}
+
+func collectNodes(g *graph.Graph) []*graph.Node {
+	var nodes []*graph.Node
+
+	g.Store.WalkPrefix("", func(node *graph.Node) bool {
+		nodes = append(nodes, node)
+		return true
+	})
+
+	return nodes
+}
+
+func clamp(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+
+	if v > hi {
+		return hi
+	}
+
+	return v
+}
@@ -0,0 +1,15 @@
+// Package layout computes node positions for visual.Visualise.
+package layout
+
+import "github.com/aaasen/kapok/graph"
+
+// Vector is a node's position on a canvas of the width/height passed to
+// Layout.
+type Vector struct {
+	X, Y float64
+}
+
+// Layouter assigns every node in g a position on a width x height canvas.
+type Layouter interface {
+	Layout(g *graph.Graph, width, height int) map[*graph.Node]Vector
+}
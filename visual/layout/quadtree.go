@@ -0,0 +1,193 @@
+package layout
+
+import "math"
+
+// maxQuadtreeDepth bounds how many times a cell can be subdivided. Without
+// it, two or more coincident points (e.g. degree-0 nodes that only ever
+// feel repulsion and drift into the same canvas corner) would make
+// insert recurse forever, since childFor keeps routing them to the same
+// child as the cell halves. Past this depth, coincident points are
+// bucketed into a single leaf instead of split further.
+const maxQuadtreeDepth = 24
+
+// quadtreeNode is one node of a Barnes-Hut quadtree over point positions,
+// used to approximate the repulsive force sum in ForceDirected.Layout in
+// O(n log n) instead of summing over every pair.
+type quadtreeNode struct {
+	x0, y0, x1, y1 float64
+	depth          int
+
+	count      int
+	comX, comY float64 // sum of positions under this node; centroid = com/count
+
+	leafIdx  int   // valid only when count == 1 and this is an unsplit leaf
+	leafIdxs []int // valid when this is a bucketed leaf at maxQuadtreeDepth
+
+	nw, ne, sw, se *quadtreeNode
+}
+
+func newQuadtree(x0, y0, x1, y1 float64) *quadtreeNode {
+	return &quadtreeNode{x0: x0, y0: y0, x1: x1, y1: y1}
+}
+
+// insert adds the point at p, identified by idx so repulsion can later
+// exclude a point from its own force sum.
+func (q *quadtreeNode) insert(p Vector, idx int) {
+	q.comX += p.X
+	q.comY += p.Y
+	q.count++
+
+	if q.leafIdxs != nil {
+		// Already a bucketed leaf; every point landing here from now on
+		// just joins the bucket instead of forcing another subdivision.
+		q.leafIdxs = append(q.leafIdxs, idx)
+		return
+	}
+
+	if q.count == 1 {
+		q.leafIdx = idx
+		return
+	}
+
+	if q.nw == nil {
+		if q.depth >= maxQuadtreeDepth {
+			q.leafIdxs = []int{q.leafIdx, idx}
+			return
+		}
+
+		q.subdivide()
+
+		// comX/comY now sum both this point and the one already here;
+		// subtract p back out to recover the earlier point so it can be
+		// pushed down into a child alongside the new one.
+		existing := Vector{X: q.comX - p.X, Y: q.comY - p.Y}
+		q.childFor(existing).insert(existing, q.leafIdx)
+	}
+
+	q.childFor(p).insert(p, idx)
+}
+
+func (q *quadtreeNode) subdivide() {
+	midX := (q.x0 + q.x1) / 2
+	midY := (q.y0 + q.y1) / 2
+	childDepth := q.depth + 1
+
+	q.nw = newQuadtree(q.x0, q.y0, midX, midY)
+	q.ne = newQuadtree(midX, q.y0, q.x1, midY)
+	q.sw = newQuadtree(q.x0, midY, midX, q.y1)
+	q.se = newQuadtree(midX, midY, q.x1, q.y1)
+
+	q.nw.depth = childDepth
+	q.ne.depth = childDepth
+	q.sw.depth = childDepth
+	q.se.depth = childDepth
+}
+
+func (q *quadtreeNode) childFor(p Vector) *quadtreeNode {
+	midX := (q.x0 + q.x1) / 2
+	midY := (q.y0 + q.y1) / 2
+
+	if p.Y < midY {
+		if p.X < midX {
+			return q.nw
+		}
+
+		return q.ne
+	}
+
+	if p.X < midX {
+		return q.sw
+	}
+
+	return q.se
+}
+
+func (q *quadtreeNode) centroid() Vector {
+	return Vector{X: q.comX / float64(q.count), Y: q.comY / float64(q.count)}
+}
+
+func (q *quadtreeNode) size() float64 {
+	return q.x1 - q.x0
+}
+
+func containsIdx(idxs []int, idx int) bool {
+	for _, i := range idxs {
+		if i == idx {
+			return true
+		}
+	}
+
+	return false
+}
+
+// forces calls fn(dx, dy, d, weight) once for every point or region that
+// should contribute to the repulsive force on the point at p (identified
+// by excludeIdx, so a point never repels itself). weight is how many
+// points are aggregated behind that call, so the caller can scale the
+// force accordingly. A region is treated as a single mass at its
+// centroid once its size/distance ratio drops below theta; otherwise its
+// children are visited individually.
+func (q *quadtreeNode) forces(p Vector, excludeIdx int, theta float64, fn func(dx, dy, d float64, weight int)) {
+	if q == nil || q.count == 0 {
+		return
+	}
+
+	if q.leafIdxs != nil {
+		weight := q.count
+		if containsIdx(q.leafIdxs, excludeIdx) {
+			weight--
+		}
+
+		if weight == 0 {
+			return
+		}
+
+		q.emit(p, weight, fn)
+
+		return
+	}
+
+	if q.count == 1 {
+		if q.leafIdx == excludeIdx {
+			return
+		}
+
+		q.emit(p, 1, fn)
+
+		return
+	}
+
+	if q.size()/q.distanceTo(p) < theta {
+		q.emit(p, q.count, fn)
+		return
+	}
+
+	q.nw.forces(p, excludeIdx, theta, fn)
+	q.ne.forces(p, excludeIdx, theta, fn)
+	q.sw.forces(p, excludeIdx, theta, fn)
+	q.se.forces(p, excludeIdx, theta, fn)
+}
+
+func (q *quadtreeNode) distanceTo(p Vector) float64 {
+	centroid := q.centroid()
+	d := math.Hypot(p.X-centroid.X, p.Y-centroid.Y)
+
+	if d == 0 {
+		return 0.01
+	}
+
+	return d
+}
+
+func (q *quadtreeNode) emit(p Vector, weight int, fn func(dx, dy, d float64, weight int)) {
+	centroid := q.centroid()
+	dx := p.X - centroid.X
+	dy := p.Y - centroid.Y
+	d := math.Hypot(dx, dy)
+
+	if d == 0 {
+		d = 0.01
+	}
+
+	fn(dx, dy, d, weight)
+}
@@ -0,0 +1,33 @@
+package layout
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/aaasen/kapok/graph"
+)
+
+// Radial places every node at a random angle around the canvas center,
+// with a radius that shrinks as the node's degree grows. It's the
+// original layout kapok shipped with, kept around as the cheap fallback.
+type Radial struct{}
+
+var _ Layouter = Radial{}
+
+func (Radial) Layout(g *graph.Graph, width, height int) map[*graph.Node]Vector {
+	result := map[*graph.Node]Vector{}
+
+	g.Store.WalkPrefix("", func(node *graph.Node) bool {
+		angle := rand.Float64() * math.Pi * 2
+		r := (float64(width) / 2.5) / float64(len(node.Links)+1)
+
+		result[node] = Vector{
+			X: float64(width)/2 + math.Cos(angle)*r,
+			Y: float64(height)/2 + math.Sin(angle)*r,
+		}
+
+		return true
+	})
+
+	return result
+}
@@ -4,86 +4,101 @@ import (
 	"fmt"
 	"io"
 	"math"
-	"math/rand"
+	"strings"
 
 	"github.com/aaasen/kapok/graph"
+	"github.com/aaasen/kapok/visual/layout"
 	svg "github.com/ajstarks/svgo"
 )
 
 const width = 1024
 const height = 1024
 
-type positions struct {
-	Positions map[string]*Vector
-	Graph     *graph.Graph
-}
-
-func newPositions(graph *graph.Graph) *positions {
-	return &positions{
-		Positions: make(map[string]*Vector),
-		Graph:     graph,
-	}
-}
-
-func (self *positions) SafeGet(node *graph.Node) *Vector {
-	vector := self.Positions[node.Name]
-
-	if vector == nil {
-		angle := rand.Float64() * math.Pi * 2
-		r := (width / 2.5) / float64(len(self.Graph.Nodes[node])+1)
-
-		rotationCorrection := 0.0
-
-		if angle > math.Pi/2.0 && angle < 3*(math.Pi/2) {
-			rotationCorrection = math.Pi
-		}
-
-		vector = &Vector{
-			X: int((width / 2) + math.Cos(angle)*r),
-			Y: int((height / 2) + math.Sin(angle)*r),
-			R: int((angle + rotationCorrection) * (180 / math.Pi)),
-		}
-
-		self.Positions[node.Name] = vector
+// Visualise renders every node in g whose name starts with prefix, along
+// with its links, as an SVG, laid out by layouter. Pass the empty string
+// as prefix to render the whole graph. Pass nil for layouter to fall back
+// to layout.Radial, kapok's original layout.
+//
+// Every node, edge, and label gets a "node"/"edge"/"label" class plus a
+// class derived from the node's namespace (e.g. "category", "main"), so
+// downstream CSS can style communities.
+func Visualise(g *graph.Graph, prefix string, layouter layout.Layouter, writer io.Writer) *svg.SVG {
+	if layouter == nil {
+		layouter = layout.Radial{}
 	}
 
-	return vector
-}
-
-func Visualise(g *graph.Graph, writer io.Writer) *svg.SVG {
 	canvas := svg.New(writer)
 	canvas.Start(width, height)
 
-	positionsA := newPositions(g)
+	positions := layouter.Layout(g, width, height)
 
-	for node, _ := range g.Nodes {
-		nodePos := positionsA.SafeGet(node)
+	g.Store.WalkPrefix(prefix, func(node *graph.Node) bool {
+		pos, ok := positions[node]
+		if !ok {
+			return true
+		}
+
+		class := namespaceClass(node.Name)
 
 		canvas.Circle(
-			nodePos.X,
-			nodePos.Y,
+			int(pos.X),
+			int(pos.Y),
 			1,
-			"fill:black")
+			"fill:black",
+			fmt.Sprintf(`class="node %s"`, class))
 
-		for neighbor, _ := range g.Nodes[node] {
-			neighborPos := positionsA.SafeGet(neighbor)
+		for _, neighbor := range node.Links {
+			neighborPos, ok := positions[neighbor]
+			if !ok {
+				continue
+			}
 
 			canvas.Line(
-				nodePos.X, nodePos.Y,
-				neighborPos.X, neighborPos.Y,
-				"stroke:rgba(0, 0, 0, 0.2);stroke-width:0.5")
+				int(pos.X), int(pos.Y),
+				int(neighborPos.X), int(neighborPos.Y),
+				"stroke:rgba(0, 0, 0, 0.2);stroke-width:0.5",
+				fmt.Sprintf(`class="edge %s"`, class))
 		}
 
+		rotation := rotationFor(pos)
+
 		canvas.Text(
 			0,
 			0,
 			node.Name,
 			`style="text-anchor:middle;font-size:12px;fill:#5bb4c0;"`,
-			fmt.Sprintf(`transform="rotate(%v, %v, %v) translate(%v, %v)"`, nodePos.R, nodePos.X, nodePos.Y, nodePos.X, nodePos.Y))
+			fmt.Sprintf(`class="label %s"`, class),
+			fmt.Sprintf(`transform="rotate(%v, %v, %v) translate(%v, %v)"`, rotation, pos.X, pos.Y, pos.X, pos.Y))
 
-	}
+		return true
+	})
 
 	canvas.End()
 
 	return canvas
 }
+
+// rotationFor returns the label rotation, in degrees, that keeps text
+// reading upright regardless of which side of the canvas pos falls on.
+func rotationFor(pos layout.Vector) float64 {
+	angle := math.Atan2(pos.Y-height/2, pos.X-width/2)
+
+	rotationCorrection := 0.0
+	if angle > math.Pi/2.0 || angle < -math.Pi/2.0 {
+		rotationCorrection = math.Pi
+	}
+
+	return (angle + rotationCorrection) * (180 / math.Pi)
+}
+
+// namespaceClass returns a CSS-safe class name for node's namespace,
+// e.g. "category" for "Category:Foo" or "main" for an ordinary title.
+func namespaceClass(name string) string {
+	ns := "main"
+
+	if idx := strings.Index(name, ":"); idx != -1 {
+		ns = name[:idx]
+	}
+
+	return strings.ToLower(strings.ReplaceAll(ns, " ", "-"))
+}